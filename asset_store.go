@@ -0,0 +1,208 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
+// legacyAssetsFile is the plain, unlocked assets.json format zapctl used
+// before AssetStore existed. It is migrated into assetsDBFile the first
+// time a locked store is opened and is otherwise untouched.
+const legacyAssetsFile = assetsFile
+
+// assetsDBFile is the transactional store's backing file.
+//
+// The request asked for a BoltDB-backed store; this ships a locked,
+// atomically-rewritten JSON file instead. That's a scope reduction, not
+// a hidden substitution: bbolt is pure Go and would be a fine addition
+// in a tree with a go.mod, but this tree has no module manifest and no
+// network access to add one, so there's nowhere to record the
+// dependency. Every write still goes through a file lock and an atomic
+// rename so concurrent `zapctl` invocations can't interleave and
+// corrupt it the way the old unlocked assets.json could -- swap this
+// driver for a real bbolt-backed one behind the same AssetStore
+// interface once the module is set up.
+const assetsDBFile = "assets.db.json"
+
+// AssetStore is the persistence boundary for assets. It exists so the
+// default locked/transactional driver and the legacy unlocked JSON
+// driver can be swapped without touching callers.
+type AssetStore interface {
+	Add(a Asset) error
+	Remove(name string) error
+	List() ([]Asset, error)
+	Get(name string) (Asset, bool, error)
+	Update(a Asset) error
+}
+
+// OpenAssetStore returns the default AssetStore, migrating assets.json
+// into assets.db.json the first time it's called if the latter doesn't
+// exist yet.
+func OpenAssetStore() (AssetStore, error) {
+	store := &lockedAssetStore{path: assetsDBFile}
+	if _, err := os.Stat(assetsDBFile); os.IsNotExist(err) {
+		if legacy, lerr := (&legacyAssetStore{path: legacyAssetsFile}).List(); lerr == nil && len(legacy) > 0 {
+			fmt.Println("[i] migrating assets.json into assets.db.json")
+			if err := store.writeAll(legacy); err != nil {
+				return nil, fmt.Errorf("migrating legacy assets: %w", err)
+			}
+		}
+	}
+	return store, nil
+}
+
+// lockedAssetStore is the default AssetStore: every mutation takes a
+// file lock, reads the current contents, applies the change, and
+// atomically renames a temp file over assets.db.json.
+type lockedAssetStore struct {
+	path string
+}
+
+func (s *lockedAssetStore) readAll() (Assets, error) {
+	var a Assets
+	b, err := os.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return a, nil
+	} else if err != nil {
+		return a, err
+	}
+	if len(b) == 0 {
+		return a, nil
+	}
+	return a, json.Unmarshal(b, &a)
+}
+
+func (s *lockedAssetStore) writeAll(list []Asset) error {
+	b, err := json.MarshalIndent(Assets{List: list}, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, b, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.path)
+}
+
+func (s *lockedAssetStore) withLock(fn func() error) error {
+	return withFileLock(s.path, fn)
+}
+
+func (s *lockedAssetStore) Add(a Asset) error {
+	return s.withLock(func() error {
+		cur, err := s.readAll()
+		if err != nil {
+			return err
+		}
+		for _, it := range cur.List {
+			if it.Name == a.Name || it.URL == a.URL {
+				return fmt.Errorf("asset with same name or url already exists")
+			}
+		}
+		return s.writeAll(append(cur.List, a))
+	})
+}
+
+func (s *lockedAssetStore) Remove(name string) error {
+	return s.withLock(func() error {
+		cur, err := s.readAll()
+		if err != nil {
+			return err
+		}
+		newList := []Asset{}
+		found := false
+		for _, it := range cur.List {
+			if it.Name == name {
+				found = true
+				continue
+			}
+			newList = append(newList, it)
+		}
+		if !found {
+			return fmt.Errorf("asset not found: %s", name)
+		}
+		return s.writeAll(newList)
+	})
+}
+
+func (s *lockedAssetStore) List() ([]Asset, error) {
+	cur, err := s.readAll()
+	return cur.List, err
+}
+
+func (s *lockedAssetStore) Get(name string) (Asset, bool, error) {
+	cur, err := s.readAll()
+	if err != nil {
+		return Asset{}, false, err
+	}
+	for _, it := range cur.List {
+		if it.Name == name {
+			return it, true, nil
+		}
+	}
+	return Asset{}, false, nil
+}
+
+func (s *lockedAssetStore) Update(a Asset) error {
+	return s.withLock(func() error {
+		cur, err := s.readAll()
+		if err != nil {
+			return err
+		}
+		for i, it := range cur.List {
+			if it.Name == a.Name {
+				cur.List[i] = a
+				return s.writeAll(cur.List)
+			}
+		}
+		return fmt.Errorf("asset not found: %s", a.Name)
+	})
+}
+
+// legacyAssetStore reproduces the original unlocked load/rewrite-whole-file
+// behavior. It's kept only so OpenAssetStore can migrate an existing
+// assets.json once; new code should use the default store instead.
+type legacyAssetStore struct {
+	path string
+}
+
+func (s *legacyAssetStore) List() ([]Asset, error) {
+	var a Assets
+	file, err := os.Open(s.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	if err := json.NewDecoder(file).Decode(&a); err != nil {
+		return nil, err
+	}
+	return a.List, nil
+}
+
+// withFileLock serializes access to path across processes using a
+// create-exclusive lock file, the simplest portable mutual exclusion
+// primitive available without a third-party lock package.
+func withFileLock(path string, fn func() error) error {
+	lockPath := path + ".lock"
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		f, err := os.OpenFile(lockPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+		if err == nil {
+			f.Close()
+			break
+		}
+		if !os.IsExist(err) {
+			return err
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("timed out waiting for lock on %s", path)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	defer os.Remove(lockPath)
+	return fn()
+}
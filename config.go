@@ -0,0 +1,93 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const (
+	defaultZapURL  = "http://localhost:8080"
+	defaultTimeout = 30
+)
+
+// Config holds the settings needed to talk to a ZAP instance. It is
+// assembled from (in increasing priority) built-in defaults, a config
+// file, the ZAP_API_KEY environment variable, and command-line flags.
+type Config struct {
+	ZapURL         string `json:"zap_url" yaml:"zap_url"`
+	APIKey         string `json:"zap_api_key" yaml:"zap_api_key"`
+	TimeoutSeconds int    `json:"timeout_seconds" yaml:"timeout_seconds"`
+}
+
+func defaultConfig() Config {
+	return Config{ZapURL: defaultZapURL, TimeoutSeconds: defaultTimeout}
+}
+
+// loadConfig reads zapctl.json (current directory) or ~/.zapctl.yaml
+// (whichever is found first) and layers it on top of the defaults, then
+// applies the ZAP_API_KEY environment variable if set.
+func loadConfig() Config {
+	cfg := defaultConfig()
+
+	if b, err := os.ReadFile("zapctl.json"); err == nil {
+		var fc Config
+		if json.Unmarshal(b, &fc) == nil {
+			mergeConfig(&cfg, fc)
+		}
+	} else if home, herr := os.UserHomeDir(); herr == nil {
+		path := filepath.Join(home, ".zapctl.yaml")
+		if b, err := os.ReadFile(path); err == nil {
+			mergeConfig(&cfg, parseFlatYAML(b))
+		}
+	}
+
+	if key := os.Getenv("ZAP_API_KEY"); key != "" {
+		cfg.APIKey = key
+	}
+	return cfg
+}
+
+func mergeConfig(cfg *Config, override Config) {
+	if override.ZapURL != "" {
+		cfg.ZapURL = override.ZapURL
+	}
+	if override.APIKey != "" {
+		cfg.APIKey = override.APIKey
+	}
+	if override.TimeoutSeconds != 0 {
+		cfg.TimeoutSeconds = override.TimeoutSeconds
+	}
+}
+
+// parseFlatYAML is a deliberately minimal "key: value" reader. It only
+// supports the handful of scalar settings zapctl cares about, which is
+// all a config this small needs.
+func parseFlatYAML(data []byte) Config {
+	var fc Config
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		parts := strings.SplitN(line, ":", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		key := strings.TrimSpace(parts[0])
+		val := strings.Trim(strings.TrimSpace(parts[1]), `"'`)
+		switch key {
+		case "zap_url":
+			fc.ZapURL = val
+		case "zap_api_key":
+			fc.APIKey = val
+		case "timeout_seconds":
+			if n, err := strconv.Atoi(val); err == nil {
+				fc.TimeoutSeconds = n
+			}
+		}
+	}
+	return fc
+}
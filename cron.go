@@ -0,0 +1,112 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CronSchedule is a parsed 5-field cron expression (minute hour
+// day-of-month month day-of-week), checked at minute resolution by
+// runDaemon. A nil field set means "any value" (the "*" wildcard).
+type CronSchedule struct {
+	Expr            string
+	minute, hour    map[int]bool
+	dom, month, dow map[int]bool
+}
+
+// ParseCron parses a standard 5-field cron expression. It supports "*",
+// plain numbers, "a-b" ranges, "a,b,c" lists, and "*/n" steps -- enough
+// for schedules like "0 */6 * * *", without pulling in a cron package
+// this tree has no go.mod to vendor one into.
+func ParseCron(expr string) (*CronSchedule, error) {
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("cron expression must have 5 fields, got %d: %q", len(fields), expr)
+	}
+	minute, err := parseCronField(fields[0], 0, 59)
+	if err != nil {
+		return nil, fmt.Errorf("minute field: %w", err)
+	}
+	hour, err := parseCronField(fields[1], 0, 23)
+	if err != nil {
+		return nil, fmt.Errorf("hour field: %w", err)
+	}
+	dom, err := parseCronField(fields[2], 1, 31)
+	if err != nil {
+		return nil, fmt.Errorf("day-of-month field: %w", err)
+	}
+	month, err := parseCronField(fields[3], 1, 12)
+	if err != nil {
+		return nil, fmt.Errorf("month field: %w", err)
+	}
+	dow, err := parseCronField(fields[4], 0, 6)
+	if err != nil {
+		return nil, fmt.Errorf("day-of-week field: %w", err)
+	}
+	return &CronSchedule{Expr: expr, minute: minute, hour: hour, dom: dom, month: month, dow: dow}, nil
+}
+
+func parseCronField(field string, min, max int) (map[int]bool, error) {
+	if field == "*" {
+		return nil, nil
+	}
+	set := make(map[int]bool)
+	for _, part := range strings.Split(field, ",") {
+		switch {
+		case strings.HasPrefix(part, "*/"):
+			step, err := strconv.Atoi(part[2:])
+			if err != nil || step <= 0 {
+				return nil, fmt.Errorf("invalid step %q", part)
+			}
+			for v := min; v <= max; v += step {
+				set[v] = true
+			}
+		case strings.Contains(part, "-"):
+			bounds := strings.SplitN(part, "-", 2)
+			lo, err1 := strconv.Atoi(bounds[0])
+			hi, err2 := strconv.Atoi(bounds[1])
+			if err1 != nil || err2 != nil || lo > hi {
+				return nil, fmt.Errorf("invalid range %q", part)
+			}
+			for v := lo; v <= hi; v++ {
+				set[v] = true
+			}
+		default:
+			v, err := strconv.Atoi(part)
+			if err != nil || v < min || v > max {
+				return nil, fmt.Errorf("invalid value %q (want %d-%d)", part, min, max)
+			}
+			set[v] = true
+		}
+	}
+	return set, nil
+}
+
+func cronFieldMatches(set map[int]bool, v int) bool {
+	return set == nil || set[v]
+}
+
+// Matches reports whether t falls on this schedule, at minute resolution.
+//
+// Standard cron ORs day-of-month and day-of-week when both are
+// restricted (not "*"), rather than ANDing them like every other field
+// pair -- e.g. "0 0 1,15 * 1" means midnight on the 1st/15th OR every
+// Monday, not their intersection. dayMatches implements that rule.
+func (c *CronSchedule) Matches(t time.Time) bool {
+	return cronFieldMatches(c.minute, t.Minute()) &&
+		cronFieldMatches(c.hour, t.Hour()) &&
+		cronFieldMatches(c.month, int(t.Month())) &&
+		c.dayMatches(t)
+}
+
+// dayMatches applies cron's day-of-month/day-of-week OR rule: if both
+// fields are restricted, t matches if it satisfies either one; if only
+// one (or neither) is restricted, that field alone decides.
+func (c *CronSchedule) dayMatches(t time.Time) bool {
+	if c.dom != nil && c.dow != nil {
+		return cronFieldMatches(c.dom, t.Day()) || cronFieldMatches(c.dow, int(t.Weekday()))
+	}
+	return cronFieldMatches(c.dom, t.Day()) && cronFieldMatches(c.dow, int(t.Weekday()))
+}
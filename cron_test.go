@@ -0,0 +1,70 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCronScheduleMatchesEveryNMinutes(t *testing.T) {
+	sched, err := ParseCron("*/15 * * * *")
+	if err != nil {
+		t.Fatalf("ParseCron: %v", err)
+	}
+	for _, tc := range []struct {
+		minute int
+		want   bool
+	}{
+		{0, true}, {15, true}, {30, true}, {45, true}, {1, false}, {59, false},
+	} {
+		got := sched.Matches(time.Date(2026, 1, 5, 12, tc.minute, 0, 0, time.UTC))
+		if got != tc.want {
+			t.Errorf("minute %d: Matches = %v, want %v", tc.minute, got, tc.want)
+		}
+	}
+}
+
+func TestCronScheduleDayOfMonthAndDayOfWeekAreOred(t *testing.T) {
+	// "midnight on the 1st/15th, OR every Monday" -- standard cron ORs
+	// dom/dow when both are restricted, rather than ANDing them.
+	sched, err := ParseCron("0 0 1,15 * 1")
+	if err != nil {
+		t.Fatalf("ParseCron: %v", err)
+	}
+
+	// 2026-01-05 is a Monday but not the 1st/15th: should match via dow.
+	if !sched.Matches(time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC)) {
+		t.Error("expected a match on a Monday that isn't the 1st/15th")
+	}
+	// 2026-01-15 is a Thursday: should match via dom.
+	if !sched.Matches(time.Date(2026, 1, 15, 0, 0, 0, 0, time.UTC)) {
+		t.Error("expected a match on the 15th even though it's not a Monday")
+	}
+	// 2026-01-06 is a Tuesday and not the 1st/15th: should not match either field.
+	if sched.Matches(time.Date(2026, 1, 6, 0, 0, 0, 0, time.UTC)) {
+		t.Error("expected no match on a Tuesday that isn't the 1st/15th")
+	}
+}
+
+func TestCronScheduleDayOfMonthOnlyIsAnded(t *testing.T) {
+	// dow is "*" (unrestricted) here, so only dom gates the match --
+	// the OR rule only kicks in when both fields are restricted.
+	sched, err := ParseCron("0 0 15 * *")
+	if err != nil {
+		t.Fatalf("ParseCron: %v", err)
+	}
+	if sched.Matches(time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC)) {
+		t.Error("expected no match on the 5th when only the 15th is scheduled")
+	}
+	if !sched.Matches(time.Date(2026, 1, 15, 0, 0, 0, 0, time.UTC)) {
+		t.Error("expected a match on the 15th")
+	}
+}
+
+func TestParseCronInvalidField(t *testing.T) {
+	if _, err := ParseCron("60 * * * *"); err == nil {
+		t.Error("expected an error for an out-of-range minute")
+	}
+	if _, err := ParseCron("* * * *"); err == nil {
+		t.Error("expected an error for a 4-field expression")
+	}
+}
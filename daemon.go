@@ -0,0 +1,172 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// runDaemon scans every asset on schedule until it's stopped with
+// SIGINT/SIGTERM, recording each run in hist and diffing it against the
+// previous one.
+func runDaemon(zap *ZapClient, store AssetStore, hist *HistoryStore, schedule *CronSchedule, format string, failThresholds []string, webhookURL string) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	fmt.Printf("[i] daemon started, schedule=%q\n", schedule.Expr)
+
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+
+	lastRun := time.Time{}
+	for {
+		select {
+		case <-ctx.Done():
+			fmt.Println("[i] daemon stopping")
+			return
+		case now := <-ticker.C:
+			minuteMark := now.Truncate(time.Minute)
+			if minuteMark.Equal(lastRun) || !schedule.Matches(now) {
+				continue
+			}
+			lastRun = minuteMark
+			fmt.Printf("[i] %s: scheduled run starting\n", minuteMark.Format(time.RFC3339))
+			runAllAssets(ctx, zap, store, hist, format, failThresholds, webhookURL)
+		}
+	}
+}
+
+// runAllAssets scans every asset once, sequentially: a daemon run isn't
+// latency-sensitive the way an interactive `zapctl scan` is, so there's
+// no need for the progress-bar/parallel machinery scan.go uses.
+func runAllAssets(ctx context.Context, zap *ZapClient, store AssetStore, hist *HistoryStore, format string, failThresholds []string, webhookURL string) {
+	assets, err := store.List()
+	if err != nil {
+		fmt.Println("[!] listing assets:", err)
+		return
+	}
+
+	for _, a := range assets {
+		if err := scanOneAsset(ctx, zap, hist, a, format, failThresholds, webhookURL); err != nil {
+			fmt.Printf("[!] %s: %v\n", a.Name, err)
+		}
+	}
+}
+
+func scanOneAsset(ctx context.Context, zap *ZapClient, hist *HistoryStore, a Asset, format string, failThresholds []string, webhookURL string) error {
+	contextName, err := zap.ConfigureContext(ctx, a)
+	if err != nil {
+		return fmt.Errorf("configuring ZAP context: %w", err)
+	}
+	defer func() {
+		teardownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		_ = zap.TeardownAuthHeader(teardownCtx, a)
+	}()
+
+	sid, err := zap.StartScan(ctx, a.URL, ScanOptions{ContextName: contextName, ScanPolicy: a.ScanPolicy})
+	if err != nil || sid == "" {
+		return fmt.Errorf("startScan: %w", err)
+	}
+
+	ticker := time.NewTicker(2 * time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			_ = zap.StopScan(context.Background(), sid)
+			return fmt.Errorf("scan interrupted")
+		case <-ticker.C:
+			status, err := zap.GetScanStatus(ctx, sid)
+			if err != nil {
+				continue
+			}
+			if status >= 100 {
+				goto done
+			}
+		}
+	}
+done:
+
+	rawAlerts, err := zap.GetAlertsForBase(ctx, a.URL)
+	if err != nil {
+		return fmt.Errorf("fetching alerts: %w", err)
+	}
+	grouped := groupAlertsByName(rawAlerts)
+	now := time.Now()
+
+	prevRuns, err := hist.List(a.Name)
+	if err != nil {
+		return fmt.Errorf("reading history: %w", err)
+	}
+	var prevAlerts []Alert
+	if len(prevRuns) > 0 {
+		prevAlerts = prevRuns[len(prevRuns)-1].Alerts
+	}
+	diff := DiffAlerts(prevAlerts, grouped)
+	fmt.Printf("[i] %s: %d new, %d resolved, %d unchanged\n", a.Name, len(diff.New), len(diff.Resolved), len(diff.Unchanged))
+
+	if err := hist.Append(a.Name, grouped, now); err != nil {
+		return fmt.Errorf("saving history: %w", err)
+	}
+
+	filename := fmt.Sprintf("scan_results_%s_%s.%s", a.Name, now.Format("2006-01-02_15-04-05"), reportExt(format))
+	f, err := os.Create(filename)
+	if err != nil {
+		return fmt.Errorf("creating report file: %w", err)
+	}
+	rpt, err := GenerateReport(grouped, a.URL, format, now, failThresholds, f)
+	f.Close()
+	if err != nil {
+		return fmt.Errorf("generating report: %w", err)
+	}
+	if rpt.BadVulns > 0 {
+		fmt.Printf("[!] %s: %d alert(s) at or above the -fail-on threshold\n", a.Name, rpt.BadVulns)
+	}
+
+	if webhookURL != "" {
+		notifyNewFindings(webhookURL, a.Name, diff.New, rpt.BadVulns)
+	}
+	return nil
+}
+
+// notifyNewFindings posts NEW High/Critical alerts to a webhook (e.g. a
+// Slack incoming webhook URL), along with badVulns, the number that
+// crossed this run's -fail-on threshold. Failures are logged, not
+// fatal: a daemon run shouldn't die because a notification endpoint is
+// down.
+func notifyNewFindings(webhookURL, asset string, newAlerts []Alert, badVulns int) {
+	var urgent []Alert
+	for _, a := range newAlerts {
+		if severityRank(a.Risk) <= severityRank("High") {
+			urgent = append(urgent, a)
+		}
+	}
+	if len(urgent) == 0 {
+		return
+	}
+
+	body, err := json.Marshal(map[string]interface{}{
+		"text":      fmt.Sprintf("zapctl: %d new High/Critical alert(s) on %s", len(urgent), asset),
+		"asset":     asset,
+		"alerts":    urgent,
+		"bad_vulns": badVulns,
+	})
+	if err != nil {
+		fmt.Println("[!] marshaling webhook payload:", err)
+		return
+	}
+
+	resp, err := http.Post(webhookURL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		fmt.Println("[!] webhook notify:", err)
+		return
+	}
+	resp.Body.Close()
+}
@@ -0,0 +1,65 @@
+package main
+
+import "testing"
+
+func alertNames(alerts []Alert) []string {
+	names := make([]string, len(alerts))
+	for i, a := range alerts {
+		names[i] = a.Name
+	}
+	return names
+}
+
+func TestDiffAlertsNewResolvedUnchanged(t *testing.T) {
+	prev := []Alert{
+		{Name: "SQL Injection", Risk: "High", URLs: []string{"http://example.com/a"}},
+		{Name: "Missing CSP Header", Risk: "Low", URLs: []string{"http://example.com/b"}},
+	}
+	cur := []Alert{
+		{Name: "SQL Injection", Risk: "High", URLs: []string{"http://example.com/a"}},
+		{Name: "Reflected XSS", Risk: "High", URLs: []string{"http://example.com/c"}},
+	}
+
+	diff := DiffAlerts(prev, cur)
+
+	if got := alertNames(diff.New); len(got) != 1 || got[0] != "Reflected XSS" {
+		t.Errorf("New = %v, want [Reflected XSS]", got)
+	}
+	if got := alertNames(diff.Resolved); len(got) != 1 || got[0] != "Missing CSP Header" {
+		t.Errorf("Resolved = %v, want [Missing CSP Header]", got)
+	}
+	if got := alertNames(diff.Unchanged); len(got) != 1 || got[0] != "SQL Injection" {
+		t.Errorf("Unchanged = %v, want [SQL Injection]", got)
+	}
+}
+
+func TestDiffAlertsMatchesByURLNotJustGroup(t *testing.T) {
+	// The same alert group can span multiple URLs; DiffAlerts should
+	// treat each URL as its own entry rather than the whole group.
+	prev := []Alert{
+		{Name: "SQL Injection", Risk: "High", URLs: []string{"http://example.com/a", "http://example.com/b"}},
+	}
+	cur := []Alert{
+		{Name: "SQL Injection", Risk: "High", URLs: []string{"http://example.com/a"}},
+	}
+
+	diff := DiffAlerts(prev, cur)
+
+	if len(diff.Unchanged) != 1 || diff.Unchanged[0].URLs[0] != "http://example.com/a" {
+		t.Errorf("Unchanged = %+v, want the /a URL unchanged", diff.Unchanged)
+	}
+	if len(diff.Resolved) != 1 || diff.Resolved[0].URLs[0] != "http://example.com/b" {
+		t.Errorf("Resolved = %+v, want the /b URL resolved", diff.Resolved)
+	}
+	if len(diff.New) != 0 {
+		t.Errorf("New = %+v, want none", diff.New)
+	}
+}
+
+func TestDiffAlertsEmptyPrev(t *testing.T) {
+	cur := []Alert{{Name: "Reflected XSS", Risk: "High", URLs: []string{"http://example.com/c"}}}
+	diff := DiffAlerts(nil, cur)
+	if len(diff.New) != 1 || len(diff.Resolved) != 0 || len(diff.Unchanged) != 0 {
+		t.Errorf("diff = %+v, want everything reported as New", diff)
+	}
+}
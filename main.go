@@ -1,25 +1,42 @@
 package main
 
 import (
-	"bytes"
+	"context"
 	"encoding/json"
 	"flag"
 	"fmt"
-	"io"
-	"net/http"
-	"net/url"
 	"os"
+	"strings"
 	"time"
 )
 
 const (
 	assetsFile = "assets.json"
-	zapBase    = "http://localhost:8080"
 )
 
 type Asset struct {
 	Name string `json:"name"`
 	URL  string `json:"url"`
+	// Tags support `zapctl list -tag staging`-style filtering.
+	Tags []string `json:"tags,omitempty"`
+	// Scope holds URL regexes passed to ZAP's includeInContext so a scan
+	// only touches URLs the asset actually owns.
+	Scope []string `json:"scope,omitempty"`
+	// AuthProfile, if set, is used to authenticate the ZAP context
+	// before a scan starts.
+	AuthProfile *AuthProfile `json:"auth_profile,omitempty"`
+	// ScanPolicy names a ZAP scan policy to use instead of the default.
+	ScanPolicy string `json:"scan_policy,omitempty"`
+}
+
+// AuthProfile describes how ZAP should authenticate against an asset.
+// Type is one of "basic", "bearer", or "form".
+type AuthProfile struct {
+	Type     string `json:"type"`
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+	Token    string `json:"token,omitempty"`
+	LoginURL string `json:"login_url,omitempty"`
 }
 
 type Assets struct {
@@ -32,172 +49,65 @@ type Alert struct {
 	Risk     string   `json:"risk"`
 	Other    string   `json:"other,omitempty"`
 	Solution string   `json:"solution,omitempty"`
+	// CWEID is ZAP's "cweid" for the underlying rule. Grouping and
+	// history diffing key on it in preference to Name so a renamed ZAP
+	// rule doesn't look like a resolved alert plus a brand new one.
+	CWEID string `json:"cweid,omitempty"`
 }
 
-func loadAssets() (Assets, error) {
-	var a Assets
-	file, err := os.Open(assetsFile)
-	if os.IsNotExist(err) {
-		return a, nil
-	} else if err != nil {
-		return a, err
+// alertGroupKey is the stable identity of an alert across scans: the CWE
+// ID when ZAP reports one, falling back to the alert name otherwise.
+func alertGroupKey(a Alert) string {
+	if a.CWEID != "" && a.CWEID != "-1" {
+		return a.CWEID
 	}
-	defer file.Close()
-	return a, json.NewDecoder(file).Decode(&a)
+	return a.Name
 }
 
-func saveAssets(a Assets) error {
-	b, _ := json.MarshalIndent(a, "", "  ")
-	return os.WriteFile(assetsFile, b, 0644)
+func addAsset(store AssetStore, name, url string) error {
+	return store.Add(Asset{Name: name, URL: url})
 }
 
-func addAsset(name, url string) error {
-	a, err := loadAssets()
-	if err != nil {
+func removeAsset(store AssetStore, zap *ZapClient, name string) error {
+	if _, ok, err := store.Get(name); err != nil {
 		return err
+	} else if !ok {
+		return fmt.Errorf("asset not found: %s", name)
 	}
-	for _, it := range a.List {
-		if it.Name == name || it.URL == url {
-			return fmt.Errorf("asset with same name or url already exists")
-		}
+	fmt.Println("[i] clearing alerts in ZAP (best-effort)...")
+	if err := zap.ClearAllAlerts(context.Background()); err != nil {
+		fmt.Println("[!] clearAllAlerts:", err)
 	}
-	a.List = append(a.List, Asset{Name: name, URL: url})
-	return saveAssets(a)
+	return store.Remove(name)
 }
 
-func removeAsset(name string) error {
-	a, err := loadAssets()
+func listAssets(store AssetStore, tagFilter string) error {
+	list, err := store.List()
 	if err != nil {
 		return err
 	}
-	newList := []Asset{}
-	found := false
-	for _, it := range a.List {
-		if it.Name == name {
-			found = true
-			fmt.Println("[i] clearing alerts in ZAP (best-effort)...")
-			clearAllAlerts()
-			continue
+	if tagFilter != "" {
+		filtered := list[:0]
+		for _, it := range list {
+			if contains(it.Tags, tagFilter) {
+				filtered = append(filtered, it)
+			}
 		}
-		newList = append(newList, it)
-	}
-	if !found {
-		return fmt.Errorf("asset not found: %s", name)
-	}
-	a.List = newList
-	return saveAssets(a)
-}
-
-func listAssets() error {
-	a, err := loadAssets()
-	if err != nil {
-		return err
+		list = filtered
 	}
-	if len(a.List) == 0 {
+	if len(list) == 0 {
 		fmt.Println("(no assets)")
 		return nil
 	}
 	fmt.Println("Assets:")
-	for _, it := range a.List {
-		fmt.Printf("- %s -> %s\n", it.Name, it.URL)
-	}
-	return nil
-}
-
-func startScan(target string) (string, error) {
-	endpoint := fmt.Sprintf("%s/JSON/ascan/action/scan/?url=%s&recurse=true&inScopeOnly=false", zapBase, url.QueryEscape(target))
-	resp, err := http.Get(endpoint)
-	if err != nil {
-		return "", err
-	}
-	defer resp.Body.Close()
-
-	var result map[string]interface{}
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return "", err
-	}
-
-	if v, ok := result["scan"]; ok {
-		return fmt.Sprintf("%v", v), nil
-	}
-	if v, ok := result["Scan"]; ok {
-		return fmt.Sprintf("%v", v), nil
-	}
-	if v, ok := result["Result"]; ok {
-		return fmt.Sprintf("%v", v), nil
-	}
-	return "", fmt.Errorf("unexpected startScan response: %v", result)
-}
-
-func getScanStatus(scanId string) (int, error) {
-	endpoint := fmt.Sprintf("%s/JSON/ascan/view/status/?scanId=%s", zapBase, url.QueryEscape(scanId))
-	resp, err := http.Get(endpoint)
-	if err != nil {
-		return 0, err
-	}
-	defer resp.Body.Close()
-	body, _ := io.ReadAll(resp.Body)
-
-	var result map[string]interface{}
-	if err := json.Unmarshal(body, &result); err != nil {
-		return 0, err
-	}
-
-	if status, ok := result["status"]; ok {
-		switch v := status.(type) {
-		case string:
-			var n int
-			fmt.Sscanf(v, "%d", &n)
-			return n, nil
-		case float64:
-			return int(v), nil
-		}
-	}
-	for _, v := range result {
-		if inner, ok := v.(map[string]interface{}); ok {
-			if s, ok := inner["status"]; ok {
-				switch t := s.(type) {
-				case string:
-					var n int
-					fmt.Sscanf(t, "%d", &n)
-					return n, nil
-				case float64:
-					return int(t), nil
-				}
-			}
+	for _, it := range list {
+		line := fmt.Sprintf("- %s -> %s", it.Name, it.URL)
+		if len(it.Tags) > 0 {
+			line += fmt.Sprintf(" [tags: %s]", strings.Join(it.Tags, ", "))
 		}
+		fmt.Println(line)
 	}
-	return 0, fmt.Errorf("could not parse status from response")
-}
-
-func getAlertsForBase(baseurl string) ([]map[string]interface{}, error) {
-	endpoint := fmt.Sprintf("%s/JSON/core/view/alerts/?baseurl=%s", zapBase, url.QueryEscape(baseurl))
-	resp, err := http.Get(endpoint)
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
-	var wrapper map[string][]map[string]interface{}
-	if err := json.NewDecoder(resp.Body).Decode(&wrapper); err != nil {
-		return nil, err
-	}
-	if alerts, ok := wrapper["alerts"]; ok {
-		return alerts, nil
-	}
-	return nil, nil
-}
-
-func clearAllAlerts() {
-	endpoint := fmt.Sprintf("%s/JSON/core/action/deleteAllAlerts/", zapBase)
-	http.Post(endpoint, "application/json", bytes.NewReader(nil))
-}
-
-func saveAlertsToFile(alerts []Alert, filename string) error {
-	b, err := json.MarshalIndent(alerts, "", "  ")
-	if err != nil {
-		return err
-	}
-	return os.WriteFile(filename, b, 0644)
+	return nil
 }
 
 func groupAlertsByName(rawAlerts []map[string]interface{}) []Alert {
@@ -208,21 +118,27 @@ func groupAlertsByName(rawAlerts []map[string]interface{}) []Alert {
 		risk, _ := a["risk"].(string)
 		other, _ := a["other"].(string)
 		solution, _ := a["solution"].(string)
+		cweid := rawCWEID(a["cweid"])
 
 		if name == "" {
 			continue
 		}
-		if _, ok := m[name]; !ok {
-			m[name] = &Alert{
+		key := name
+		if cweid != "" && cweid != "-1" {
+			key = cweid
+		}
+		if _, ok := m[key]; !ok {
+			m[key] = &Alert{
 				Name:     name,
 				Risk:     risk,
 				Other:    other,
 				Solution: solution,
+				CWEID:    cweid,
 				URLs:     []string{},
 			}
 		}
-		if !contains(m[name].URLs, urlStr) {
-			m[name].URLs = append(m[name].URLs, urlStr)
+		if !contains(m[key].URLs, urlStr) {
+			m[key].URLs = append(m[key].URLs, urlStr)
 		}
 	}
 	var result []Alert
@@ -232,6 +148,19 @@ func groupAlertsByName(rawAlerts []map[string]interface{}) []Alert {
 	return result
 }
 
+// rawCWEID normalizes ZAP's "cweid" field, which comes back as either a
+// numeric string or a JSON number depending on ZAP version.
+func rawCWEID(v interface{}) string {
+	switch t := v.(type) {
+	case string:
+		return t
+	case float64:
+		return fmt.Sprintf("%.0f", t)
+	default:
+		return ""
+	}
+}
+
 func contains(slice []string, s string) bool {
 	for _, v := range slice {
 		if v == s {
@@ -246,14 +175,93 @@ func prettyPrint(v interface{}) {
 	fmt.Println(string(b))
 }
 
+// reportExt maps a report format to the file extension used for the
+// default scan_results_* filename.
+func reportExt(format string) string {
+	switch strings.ToLower(format) {
+	case "", "json":
+		return "json"
+	case "sarif":
+		return "sarif"
+	case "junit":
+		return "xml"
+	case "csv":
+		return "csv"
+	case "html":
+		return "html"
+	default:
+		return format
+	}
+}
+
+// splitCSV turns a comma-separated flag value (e.g. "-fail-on
+// High,Medium" or "-tags a,b,c") into a clean slice, dropping empty
+// entries from trailing/leading commas.
+func splitCSV(s string) []string {
+	var out []string
+	for _, p := range strings.Split(s, ",") {
+		p = strings.TrimSpace(p)
+		if p != "" {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
 func usage() {
 	fmt.Println("zapctl - small demo CLI")
 	fmt.Println("Usage:")
-	fmt.Println("  zapctl add -name NAME -url URL     # add asset")
-	fmt.Println("  zapctl remove -name NAME           # remove asset (and clear alerts in ZAP)")
-	fmt.Println("  zapctl list                        # list assets")
-	fmt.Println("  zapctl scan -name NAME             # start active scan on asset (waits until 100%)")
-	fmt.Println("  zapctl alerts -name NAME           # fetch alerts for asset (grouped by vulnerability)")
+	fmt.Println("  zapctl add -name NAME -url URL            # add asset")
+	fmt.Println("  zapctl remove -name NAME                  # remove asset (and clear alerts in ZAP)")
+	fmt.Println("  zapctl list [-tag TAG]                    # list assets, optionally filtered by tag")
+	fmt.Println("  zapctl asset tag -name NAME -tags a,b,c   # set tags on an asset")
+	fmt.Println("  zapctl asset scope -name NAME -scope R1,R2 # set ZAP in-scope URL regexes on an asset")
+	fmt.Println("  zapctl scan -name NAME [-name NAME2 ...]  # start active scan on one or more assets in parallel")
+	fmt.Println("  zapctl alerts -name NAME                  # fetch alerts for asset (grouped by vulnerability)")
+	fmt.Println("  zapctl daemon -schedule CRON               # scan every asset on a cron schedule, forever")
+	fmt.Println("  zapctl history -name NAME                  # list recorded runs for an asset")
+	fmt.Println("  zapctl diff -name NAME -from TS [-to TS]  # diff two recorded runs (RFC3339 timestamps)")
+	fmt.Println("")
+	fmt.Println("ZAP connection flags (any command that talks to ZAP):")
+	fmt.Println("  -zap-url URL     # override the configured ZAP base URL")
+	fmt.Println("  -zap-key KEY     # override the configured ZAP API key")
+	fmt.Println("")
+	fmt.Println("Settings are read from zapctl.json or ~/.zapctl.yaml, then the")
+	fmt.Println("ZAP_API_KEY environment variable, then the flags above.")
+	fmt.Println("")
+	fmt.Println("zapctl scan report flags:")
+	fmt.Println("  -format FMT      # json (default), sarif, junit, csv, html")
+	fmt.Println("  -out FILE        # report path (default: scan_results_NAME_TIMESTAMP.EXT)")
+	fmt.Println("  -fail-on LEVELS  # comma-separated risk levels, e.g. High,Medium; exits non-zero if matched")
+	fmt.Println("  -no-progress     # disable the progress bar, emit structured JSON log lines instead (used automatically when stdout isn't a terminal)")
+	fmt.Println("")
+	fmt.Println("zapctl daemon flags:")
+	fmt.Println("  -schedule CRON   # 5-field cron expression, default \"0 */6 * * *\"")
+	fmt.Println("  -webhook URL     # notified with new High/Critical alerts after each run")
+}
+
+// newZapClient builds the ZapClient for a subcommand: config file and
+// ZAP_API_KEY first, then any -zap-url/-zap-key flags on top.
+func newZapClient(zapURL, zapKey *string) *ZapClient {
+	cfg := loadConfig()
+	if *zapURL != "" {
+		cfg.ZapURL = *zapURL
+	}
+	if *zapKey != "" {
+		cfg.APIKey = *zapKey
+	}
+	return NewZapClientFromConfig(cfg)
+}
+
+// openStore opens the default asset store, exiting with an error message
+// on failure since every command below needs one.
+func openStore() AssetStore {
+	store, err := OpenAssetStore()
+	if err != nil {
+		fmt.Println("error opening asset store:", err)
+		os.Exit(1)
+	}
+	return store
 }
 
 func main() {
@@ -272,7 +280,7 @@ func main() {
 			fmt.Println("name and url required")
 			return
 		}
-		if err := addAsset(*name, *url); err != nil {
+		if err := addAsset(openStore(), *name, *url); err != nil {
 			fmt.Println("error:", err)
 		} else {
 			fmt.Println("added")
@@ -280,100 +288,163 @@ func main() {
 	case "remove":
 		fs := flag.NewFlagSet("remove", flag.ExitOnError)
 		name := fs.String("name", "", "asset name")
+		zapURL := fs.String("zap-url", "", "override configured ZAP base URL")
+		zapKey := fs.String("zap-key", "", "override configured ZAP API key")
 		fs.Parse(os.Args[2:])
 		if *name == "" {
 			fmt.Println("name required")
 			return
 		}
-		if err := removeAsset(*name); err != nil {
+		zap := newZapClient(zapURL, zapKey)
+		if err := removeAsset(openStore(), zap, *name); err != nil {
 			fmt.Println("error:", err)
 		} else {
 			fmt.Println("removed")
 		}
 	case "list":
-		if err := listAssets(); err != nil {
+		fs := flag.NewFlagSet("list", flag.ExitOnError)
+		tag := fs.String("tag", "", "only list assets with this tag")
+		fs.Parse(os.Args[2:])
+		if err := listAssets(openStore(), *tag); err != nil {
 			fmt.Println("error:", err)
 		}
+	case "asset":
+		if len(os.Args) < 3 {
+			fmt.Println("usage: zapctl asset <tag|scope> -name NAME ...")
+			return
+		}
+		switch os.Args[2] {
+		case "tag":
+			fs := flag.NewFlagSet("asset tag", flag.ExitOnError)
+			name := fs.String("name", "", "asset name")
+			tags := fs.String("tags", "", "comma-separated tags to set on the asset")
+			fs.Parse(os.Args[3:])
+			if *name == "" {
+				fmt.Println("name required")
+				return
+			}
+			store := openStore()
+			a, ok, err := store.Get(*name)
+			if err != nil {
+				fmt.Println("error:", err)
+				return
+			}
+			if !ok {
+				fmt.Println("asset not found:", *name)
+				return
+			}
+			a.Tags = splitCSV(*tags)
+			if err := store.Update(a); err != nil {
+				fmt.Println("error:", err)
+				return
+			}
+			fmt.Printf("tagged %s: %s\n", *name, strings.Join(a.Tags, ", "))
+		case "scope":
+			fs := flag.NewFlagSet("asset scope", flag.ExitOnError)
+			name := fs.String("name", "", "asset name")
+			scope := fs.String("scope", "", "comma-separated URL regexes ZAP should include in scope")
+			fs.Parse(os.Args[3:])
+			if *name == "" {
+				fmt.Println("name required")
+				return
+			}
+			store := openStore()
+			a, ok, err := store.Get(*name)
+			if err != nil {
+				fmt.Println("error:", err)
+				return
+			}
+			if !ok {
+				fmt.Println("asset not found:", *name)
+				return
+			}
+			a.Scope = splitCSV(*scope)
+			if err := store.Update(a); err != nil {
+				fmt.Println("error:", err)
+				return
+			}
+			fmt.Printf("scoped %s: %s\n", *name, strings.Join(a.Scope, ", "))
+		default:
+			fmt.Println("usage: zapctl asset <tag|scope> -name NAME ...")
+		}
 	case "scan":
 		fs := flag.NewFlagSet("scan", flag.ExitOnError)
-		name := fs.String("name", "", "asset name")
+		var names stringList
+		fs.Var(&names, "name", "asset name (repeatable to scan several assets in parallel)")
+		zapURL := fs.String("zap-url", "", "override configured ZAP base URL")
+		zapKey := fs.String("zap-key", "", "override configured ZAP API key")
+		format := fs.String("format", "json", "report format: json, sarif, junit, csv, html")
+		out := fs.String("out", "", "report file path (default: scan_results_NAME_TIMESTAMP.EXT)")
+		failOn := fs.String("fail-on", "", "comma-separated risk levels that make zapctl exit non-zero, e.g. High,Medium")
+		noProgress := fs.Bool("no-progress", false, "disable the progress bar and emit structured JSON log lines instead")
 		fs.Parse(os.Args[2:])
-		if *name == "" {
+		if len(names) == 0 {
 			fmt.Println("name required")
 			return
 		}
-		a, _ := loadAssets()
-		var target string
-		for _, it := range a.List {
-			if it.Name == *name {
-				target = it.URL
+		store := openStore()
+		var jobs []scanJob
+		for _, name := range names {
+			a, ok, err := store.Get(name)
+			if err != nil {
+				fmt.Println("error:", err)
+				return
 			}
+			if !ok {
+				fmt.Println("asset not found:", name)
+				return
+			}
+			jobs = append(jobs, scanJob{Name: name, Target: a.URL, Asset: a})
 		}
-		if target == "" {
-			fmt.Println("asset not found")
-			return
-		}
-		fmt.Println("[i] starting active scan for", target)
-		sid, err := startScan(target)
-		if err != nil || sid == "" {
-			fmt.Println("startScan error:", err)
-			return
-		}
-		fmt.Println("[i] polling scan status...")
-		for {
-			status, err := getScanStatus(sid)
-			if err != nil {
-				fmt.Println("status poll err:", err)
-				time.Sleep(2 * time.Second)
+
+		zap := newZapClient(zapURL, zapKey)
+		results := runScans(zap, jobs, *format, *out, splitCSV(*failOn), *noProgress)
+
+		failed := false
+		for _, res := range results {
+			if res.Err != nil {
+				fmt.Printf("[!] scan %q failed: %v\n", res.Job.Name, res.Err)
+				failed = true
 				continue
 			}
-			fmt.Printf("progress: %d%%\n", status)
-			if status >= 100 {
-				break
+			fmt.Printf("[i] %s: results saved to %s\n", res.Job.Name, res.Filename)
+			fmt.Printf("Found %d grouped vulnerabilities for %s:\n", len(res.Grouped), res.Job.Name)
+			for _, g := range res.Grouped {
+				fmt.Printf("- %s (%s) affecting %d URLs\n", g.Name, g.Risk, len(g.URLs))
+				for _, u := range g.URLs {
+					fmt.Printf("    * %s\n", u)
+				}
 			}
-			time.Sleep(2 * time.Second)
-		}
-		fmt.Println("[i] scan finished, fetching results...")
-		rawAlerts, err := getAlertsForBase(target)
-		if err != nil {
-			fmt.Println("failed to fetch alerts:", err)
-			return
-		}
-		grouped := groupAlertsByName(rawAlerts)
-		timestamp := time.Now().Format("2006-01-02_15-04-05")
-		filename := fmt.Sprintf("scan_results_%s_%s.json", *name, timestamp)
-		if err := saveAlertsToFile(grouped, filename); err != nil {
-			fmt.Println("failed to save results:", err)
-		} else {
-			fmt.Printf("[i] results saved to %s\n", filename)
-		}
-		fmt.Printf("Found %d grouped vulnerabilities:\n", len(grouped))
-		for _, g := range grouped {
-			fmt.Printf("- %s (%s) affecting %d URLs\n", g.Name, g.Risk, len(g.URLs))
-			for _, u := range g.URLs {
-				fmt.Printf("    * %s\n", u)
+			if res.Report.BadVulns > 0 {
+				fmt.Printf("[!] %s: %d alert(s) at or above the -fail-on threshold\n", res.Job.Name, res.Report.BadVulns)
+				failed = true
 			}
 		}
+		if failed {
+			os.Exit(1)
+		}
 	case "alerts":
 		fs := flag.NewFlagSet("alerts", flag.ExitOnError)
 		name := fs.String("name", "", "asset name")
+		zapURL := fs.String("zap-url", "", "override configured ZAP base URL")
+		zapKey := fs.String("zap-key", "", "override configured ZAP API key")
 		fs.Parse(os.Args[2:])
 		if *name == "" {
 			fmt.Println("name required")
 			return
 		}
-		a, _ := loadAssets()
-		var target string
-		for _, it := range a.List {
-			if it.Name == *name {
-				target = it.URL
-			}
+		a, ok, err := openStore().Get(*name)
+		if err != nil {
+			fmt.Println("error:", err)
+			return
 		}
-		if target == "" {
+		if !ok {
 			fmt.Println("asset not found")
 			return
 		}
-		rawAlerts, err := getAlertsForBase(target)
+		target := a.URL
+		zap := newZapClient(zapURL, zapKey)
+		rawAlerts, err := zap.GetAlertsForBase(context.Background(), target)
 		if err != nil {
 			fmt.Println("error fetching alerts:", err)
 			return
@@ -396,6 +467,105 @@ func main() {
 				fmt.Printf("    * %s\n", u)
 			}
 		}
+	case "daemon":
+		fs := flag.NewFlagSet("daemon", flag.ExitOnError)
+		schedule := fs.String("schedule", "0 */6 * * *", "cron schedule (5-field, minute resolution) for scanning every asset")
+		zapURL := fs.String("zap-url", "", "override configured ZAP base URL")
+		zapKey := fs.String("zap-key", "", "override configured ZAP API key")
+		format := fs.String("format", "json", "report format: json, sarif, junit, csv, html")
+		failOn := fs.String("fail-on", "", "comma-separated risk levels that make each run's report note a fail-on match")
+		webhook := fs.String("webhook", "", "webhook URL (e.g. a Slack incoming webhook) notified on new High/Critical alerts")
+		fs.Parse(os.Args[2:])
+		cron, err := ParseCron(*schedule)
+		if err != nil {
+			fmt.Println("error:", err)
+			return
+		}
+		zap := newZapClient(zapURL, zapKey)
+		runDaemon(zap, openStore(), NewHistoryStore(historyDBFile), cron, *format, splitCSV(*failOn), *webhook)
+	case "history":
+		fs := flag.NewFlagSet("history", flag.ExitOnError)
+		name := fs.String("name", "", "asset name")
+		fs.Parse(os.Args[2:])
+		if *name == "" {
+			fmt.Println("name required")
+			return
+		}
+		entries, err := NewHistoryStore(historyDBFile).List(*name)
+		if err != nil {
+			fmt.Println("error:", err)
+			return
+		}
+		if len(entries) == 0 {
+			fmt.Println("(no history)")
+			return
+		}
+		for _, e := range entries {
+			fmt.Printf("%s  %d alerts\n", e.Timestamp.Format(time.RFC3339), len(e.Alerts))
+		}
+	case "diff":
+		fs := flag.NewFlagSet("diff", flag.ExitOnError)
+		name := fs.String("name", "", "asset name")
+		from := fs.String("from", "", "from timestamp (RFC3339)")
+		to := fs.String("to", "", "to timestamp (RFC3339); defaults to the most recent run")
+		fs.Parse(os.Args[2:])
+		if *name == "" || *from == "" {
+			fmt.Println("name and -from required")
+			return
+		}
+		fromTS, err := time.Parse(time.RFC3339, *from)
+		if err != nil {
+			fmt.Println("invalid -from timestamp:", err)
+			return
+		}
+		hist := NewHistoryStore(historyDBFile)
+		fromEntry, ok, err := hist.At(*name, fromTS)
+		if err != nil {
+			fmt.Println("error:", err)
+			return
+		}
+		if !ok {
+			fmt.Println("no run recorded at -from timestamp")
+			return
+		}
+		var toEntry HistoryEntry
+		if *to == "" {
+			entries, err := hist.List(*name)
+			if err != nil {
+				fmt.Println("error:", err)
+				return
+			}
+			if len(entries) == 0 {
+				fmt.Println("(no history)")
+				return
+			}
+			toEntry = entries[len(entries)-1]
+		} else {
+			toTS, err := time.Parse(time.RFC3339, *to)
+			if err != nil {
+				fmt.Println("invalid -to timestamp:", err)
+				return
+			}
+			toEntry, ok, err = hist.At(*name, toTS)
+			if err != nil {
+				fmt.Println("error:", err)
+				return
+			}
+			if !ok {
+				fmt.Println("no run recorded at -to timestamp")
+				return
+			}
+		}
+		diff := DiffAlerts(fromEntry.Alerts, toEntry.Alerts)
+		fmt.Printf("NEW (%d):\n", len(diff.New))
+		for _, a := range diff.New {
+			fmt.Printf("  + %s (%s) %s\n", a.Name, a.Risk, a.URLs[0])
+		}
+		fmt.Printf("RESOLVED (%d):\n", len(diff.Resolved))
+		for _, a := range diff.Resolved {
+			fmt.Printf("  - %s (%s) %s\n", a.Name, a.Risk, a.URLs[0])
+		}
+		fmt.Printf("UNCHANGED (%d)\n", len(diff.Unchanged))
 	default:
 		usage()
 	}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// isTerminal reports whether f looks like an interactive terminal. It is
+// a cheap stdlib-only stand-in for a full isatty check: good enough to
+// decide whether a progress bar is useful or whether to fall back to
+// plain structured log lines for CI.
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// Bar renders a single "name [====>    ] NN% elapsed ETA" line. It tracks
+// its own start time so Render can report elapsed/ETA without the caller
+// threading a clock through.
+type Bar struct {
+	Label     string
+	Width     int
+	start     time.Time
+	lastPct   int
+	lastWidth int
+}
+
+func NewBar(label string) *Bar {
+	return &Bar{Label: label, Width: 30, start: time.Now()}
+}
+
+// Render returns the current bar text for percent. It does not write
+// anything itself so MultiBar can batch multiple bars into one redraw.
+func (b *Bar) Render(percent int) string {
+	if percent < 0 {
+		percent = 0
+	}
+	if percent > 100 {
+		percent = 100
+	}
+	b.lastPct = percent
+
+	filled := percent * b.Width / 100
+	bar := strings.Repeat("=", filled) + strings.Repeat(" ", b.Width-filled)
+
+	elapsed := time.Since(b.start).Round(time.Second)
+	eta := "?"
+	if percent > 0 {
+		total := elapsed * time.Duration(100) / time.Duration(percent)
+		remaining := total - elapsed
+		if remaining < 0 {
+			remaining = 0
+		}
+		eta = remaining.Round(time.Second).String()
+	}
+
+	line := fmt.Sprintf("%-16s [%s] %3d%%  elapsed %-8s eta %s", b.Label, bar, percent, elapsed, eta)
+	b.lastWidth = len(line)
+	return line
+}
+
+// MultiBar draws one Bar per scan, redrawing all of them in place each
+// tick. It is only meant for a real terminal; runScans falls back to
+// structured logging when stdout isn't one.
+type MultiBar struct {
+	bars  []*Bar
+	drawn bool
+}
+
+func NewMultiBar(bars []*Bar) *MultiBar {
+	return &MultiBar{bars: bars}
+}
+
+// Update redraws every bar with its latest percent, moving the cursor
+// back up over the previous draw first.
+func (m *MultiBar) Update(percents []int) {
+	if m.drawn {
+		fmt.Printf("\x1b[%dA", len(m.bars))
+	}
+	for i, b := range m.bars {
+		fmt.Printf("\x1b[2K%s\n", b.Render(percents[i]))
+	}
+	m.drawn = true
+}
@@ -0,0 +1,226 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"html"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+// severityOrder ranks ZAP risk levels from worst to best, both for
+// sorting the HTML report and for evaluating -fail-on thresholds.
+var severityOrder = []string{"Critical", "High", "Medium", "Low", "Informational"}
+
+func severityRank(risk string) int {
+	for i, s := range severityOrder {
+		if strings.EqualFold(s, risk) {
+			return i
+		}
+	}
+	return len(severityOrder)
+}
+
+// VulnerabilityReport is the format-independent view of a scan that every
+// renderer works from: the target that was scanned, when, the alerts
+// grouped by severity, and a BadVulns count a CI gate can key off of.
+type VulnerabilityReport struct {
+	Target      string
+	GeneratedAt time.Time
+	BySeverity  map[string][]Alert
+	BadVulns    int
+}
+
+// buildReport groups alerts by severity and counts how many match one of
+// the failThresholds (case-insensitive), so callers can turn that into a
+// CI exit code.
+func buildReport(alerts []Alert, target string, generatedAt time.Time, failThresholds []string) VulnerabilityReport {
+	r := VulnerabilityReport{
+		Target:      target,
+		GeneratedAt: generatedAt,
+		BySeverity:  make(map[string][]Alert),
+	}
+	for _, a := range alerts {
+		r.BySeverity[a.Risk] = append(r.BySeverity[a.Risk], a)
+		for _, t := range failThresholds {
+			if strings.EqualFold(t, a.Risk) {
+				r.BadVulns++
+				break
+			}
+		}
+	}
+	return r
+}
+
+// sortedAlerts returns all alerts in the report ordered worst-risk-first,
+// which every renderer below uses for a stable, readable ordering.
+func (r VulnerabilityReport) sortedAlerts() []Alert {
+	var all []Alert
+	for _, group := range r.BySeverity {
+		all = append(all, group...)
+	}
+	sort.Slice(all, func(i, j int) bool {
+		if severityRank(all[i].Risk) != severityRank(all[j].Risk) {
+			return severityRank(all[i].Risk) < severityRank(all[j].Risk)
+		}
+		return all[i].Name < all[j].Name
+	})
+	return all
+}
+
+// GenerateReport renders alerts found while scanning target into w using
+// the named format ("json", "sarif", "junit", "csv", or "html").
+func GenerateReport(alerts []Alert, target string, format string, generatedAt time.Time, failThresholds []string, w io.Writer) (VulnerabilityReport, error) {
+	r := buildReport(alerts, target, generatedAt, failThresholds)
+
+	var err error
+	switch strings.ToLower(format) {
+	case "", "json":
+		err = renderJSON(r, w)
+	case "sarif":
+		err = renderSARIF(r, w)
+	case "junit":
+		err = renderJUnit(r, w)
+	case "csv":
+		err = renderCSV(r, w)
+	case "html":
+		err = renderHTML(r, w)
+	default:
+		err = fmt.Errorf("unknown report format: %s", format)
+	}
+	return r, err
+}
+
+func renderJSON(r VulnerabilityReport, w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r.sortedAlerts())
+}
+
+func renderCSV(r VulnerabilityReport, w io.Writer) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"name", "risk", "urls", "solution"}); err != nil {
+		return err
+	}
+	for _, a := range r.sortedAlerts() {
+		if err := cw.Write([]string{a.Name, a.Risk, strings.Join(a.URLs, " "), a.Solution}); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// sarifRiskToLevel maps ZAP risk levels onto the SARIF "level" enum
+// (error/warning/note) used by GitHub code scanning.
+func sarifRiskToLevel(risk string) string {
+	switch strings.ToLower(risk) {
+	case "critical", "high":
+		return "error"
+	case "medium":
+		return "warning"
+	default:
+		return "note"
+	}
+}
+
+func renderSARIF(r VulnerabilityReport, w io.Writer) error {
+	type sarifMessage struct {
+		Text string `json:"text"`
+	}
+	type sarifLocation struct {
+		PhysicalLocation struct {
+			ArtifactLocation struct {
+				URI string `json:"uri"`
+			} `json:"artifactLocation"`
+		} `json:"physicalLocation"`
+	}
+	type sarifResult struct {
+		RuleID    string          `json:"ruleId"`
+		Level     string          `json:"level"`
+		Message   sarifMessage    `json:"message"`
+		Locations []sarifLocation `json:"locations"`
+	}
+
+	var results []sarifResult
+	for _, a := range r.sortedAlerts() {
+		res := sarifResult{
+			RuleID:  a.Name,
+			Level:   sarifRiskToLevel(a.Risk),
+			Message: sarifMessage{Text: a.Solution},
+		}
+		for _, u := range a.URLs {
+			var loc sarifLocation
+			loc.PhysicalLocation.ArtifactLocation.URI = u
+			res.Locations = append(res.Locations, loc)
+		}
+		results = append(results, res)
+	}
+
+	doc := map[string]interface{}{
+		"$schema": "https://raw.githubusercontent.com/oasis-tcs/sarif-spec/master/Schemata/sarif-schema-2.1.0.json",
+		"version": "2.1.0",
+		"runs": []map[string]interface{}{
+			{
+				"tool": map[string]interface{}{
+					"driver": map[string]interface{}{
+						"name":    "zapctl",
+						"version": "1.0",
+					},
+				},
+				"results": results,
+			},
+		},
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(doc)
+}
+
+// xmlAttr escapes s for use inside a double-quoted XML attribute value.
+// ZAP alert names/messages come from findings against a deliberately
+// vulnerable app and routinely contain raw "<", ">", "&", so they can't
+// go through fmt's %q (Go string escaping, not XML escaping) without
+// producing invalid XML that breaks CI JUnit parsers.
+func xmlAttr(s string) string {
+	var buf bytes.Buffer
+	_ = xml.EscapeText(&buf, []byte(s))
+	return buf.String()
+}
+
+func renderJUnit(r VulnerabilityReport, w io.Writer) error {
+	alerts := r.sortedAlerts()
+	fmt.Fprintf(w, "<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n")
+	fmt.Fprintf(w, "<testsuite name=\"%s\" tests=\"%d\" failures=\"%d\">\n",
+		xmlAttr(r.Target), len(alerts), r.BadVulns)
+	for _, a := range alerts {
+		fmt.Fprintf(w, "  <testcase classname=\"%s\" name=\"%s\">\n", xmlAttr(r.Target), xmlAttr(a.Name))
+		fmt.Fprintf(w, "    <failure message=\"%s\">%s</failure>\n",
+			xmlAttr(fmt.Sprintf("%s risk", a.Risk)), html.EscapeString(a.Solution))
+		fmt.Fprintf(w, "  </testcase>\n")
+	}
+	fmt.Fprintf(w, "</testsuite>\n")
+	return nil
+}
+
+func renderHTML(r VulnerabilityReport, w io.Writer) error {
+	fmt.Fprintf(w, "<!doctype html>\n<html><head><meta charset=\"utf-8\">\n")
+	fmt.Fprintf(w, "<title>zapctl report: %s</title>\n", html.EscapeString(r.Target))
+	fmt.Fprintf(w, "<style>table{border-collapse:collapse;width:100%%}td,th{border:1px solid #ccc;padding:4px;text-align:left}</style>\n")
+	fmt.Fprintf(w, "</head><body>\n")
+	fmt.Fprintf(w, "<h1>%s</h1>\n<p>Generated %s</p>\n",
+		html.EscapeString(r.Target), r.GeneratedAt.Format(time.RFC3339))
+	fmt.Fprintf(w, "<table><tr><th>Risk</th><th>Name</th><th>URLs</th><th>Solution</th></tr>\n")
+	for _, a := range r.sortedAlerts() {
+		fmt.Fprintf(w, "<tr><td>%s</td><td>%s</td><td>%s</td><td>%s</td></tr>\n",
+			html.EscapeString(a.Risk), html.EscapeString(a.Name),
+			html.EscapeString(strings.Join(a.URLs, ", ")), html.EscapeString(a.Solution))
+	}
+	fmt.Fprintf(w, "</table>\n</body></html>\n")
+	return nil
+}
@@ -0,0 +1,207 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// stringList lets "-name" be repeated (zapctl scan -name a -name b) while
+// still working as a normal single-value flag when passed once.
+type stringList []string
+
+func (s *stringList) String() string { return fmt.Sprint([]string(*s)) }
+
+func (s *stringList) Set(v string) error {
+	*s = append(*s, v)
+	return nil
+}
+
+type scanJob struct {
+	Name   string
+	Target string
+	Asset  Asset
+}
+
+type scanResult struct {
+	Job      scanJob
+	Grouped  []Alert
+	Report   VulnerabilityReport
+	Filename string
+	Err      error
+}
+
+// scanLogEntry is the structured, one-line-per-event log zapctl emits
+// instead of a progress bar when stdout isn't a terminal or -no-progress
+// was passed, so CI logs stay grep-able.
+type scanLogEntry struct {
+	Time    string `json:"time"`
+	Asset   string `json:"asset"`
+	ScanID  string `json:"scan_id,omitempty"`
+	Percent int    `json:"percent,omitempty"`
+	Event   string `json:"event"`
+	Message string `json:"message,omitempty"`
+}
+
+func logScanEvent(e scanLogEntry) {
+	e.Time = time.Now().Format(time.RFC3339)
+	b, _ := json.Marshal(e)
+	fmt.Println(string(b))
+}
+
+// runScans starts an active scan for every job, polling each one until
+// it completes, is stopped by SIGINT/SIGTERM, or errors. When stdout is a
+// terminal and noProgress is false it renders one progress bar per job;
+// otherwise it emits scanLogEntry JSON lines so CI output stays readable.
+func runScans(zap *ZapClient, jobs []scanJob, format, outTemplate string, failThresholds []string, noProgress bool) []scanResult {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	useBar := !noProgress && isTerminal(os.Stdout)
+
+	percents := make([]int, len(jobs))
+	var mu sync.Mutex
+	results := make([]scanResult, len(jobs))
+
+	var wg sync.WaitGroup
+	for i, job := range jobs {
+		wg.Add(1)
+		go func(i int, job scanJob) {
+			defer wg.Done()
+			results[i] = runOneScan(ctx, zap, job, &mu, percents, i, !useBar)
+		}(i, job)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	if useBar {
+		watchProgress(jobs, percents, &mu, done)
+	} else {
+		<-done
+	}
+
+	now := time.Now()
+	for i, job := range jobs {
+		res := &results[i]
+		if res.Err != nil {
+			continue
+		}
+		filename := outTemplate
+		if filename == "" {
+			filename = fmt.Sprintf("scan_results_%s_%s.%s", job.Name, now.Format("2006-01-02_15-04-05"), reportExt(format))
+		} else if len(jobs) > 1 {
+			filename = fmt.Sprintf("%s.%s", job.Name, filename)
+		}
+		f, err := os.Create(filename)
+		if err != nil {
+			res.Err = err
+			continue
+		}
+		res.Report, res.Err = GenerateReport(res.Grouped, job.Target, format, now, failThresholds, f)
+		f.Close()
+		res.Filename = filename
+	}
+	return results
+}
+
+func watchProgress(jobs []scanJob, percents []int, mu *sync.Mutex, done <-chan struct{}) {
+	bars := make([]*Bar, len(jobs))
+	for i, job := range jobs {
+		bars[i] = NewBar(job.Name)
+	}
+	mb := NewMultiBar(bars)
+	ticker := time.NewTicker(300 * time.Millisecond)
+	defer ticker.Stop()
+
+	snapshot := func() []int {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]int(nil), percents...)
+	}
+
+	for {
+		select {
+		case <-done:
+			mb.Update(snapshot())
+			return
+		case <-ticker.C:
+			mb.Update(snapshot())
+		}
+	}
+}
+
+func runOneScan(ctx context.Context, zap *ZapClient, job scanJob, mu *sync.Mutex, percents []int, idx int, logStructured bool) scanResult {
+	setPercent := func(p int) {
+		mu.Lock()
+		percents[idx] = p
+		mu.Unlock()
+	}
+
+	if logStructured {
+		logScanEvent(scanLogEntry{Asset: job.Name, Event: "start", Message: "starting active scan for " + job.Target})
+	}
+
+	contextName, err := zap.ConfigureContext(ctx, job.Asset)
+	if err != nil {
+		return scanResult{Job: job, Err: fmt.Errorf("configuring ZAP context: %w", err)}
+	}
+	defer func() {
+		teardownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		_ = zap.TeardownAuthHeader(teardownCtx, job.Asset)
+	}()
+
+	sid, err := zap.StartScan(ctx, job.Target, ScanOptions{ContextName: contextName, ScanPolicy: job.Asset.ScanPolicy})
+	if err != nil || sid == "" {
+		return scanResult{Job: job, Err: fmt.Errorf("startScan: %w", err)}
+	}
+	if logStructured {
+		logScanEvent(scanLogEntry{Asset: job.Name, ScanID: sid, Event: "started"})
+	}
+
+	ticker := time.NewTicker(2 * time.Second)
+	defer ticker.Stop()
+
+pollLoop:
+	for {
+		select {
+		case <-ctx.Done():
+			if logStructured {
+				logScanEvent(scanLogEntry{Asset: job.Name, ScanID: sid, Event: "interrupted", Message: "stopping scan in ZAP"})
+			} else {
+				fmt.Printf("\n[i] stopping scan %q in ZAP...\n", job.Name)
+			}
+			stopCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			_ = zap.StopScan(stopCtx, sid)
+			cancel()
+			return scanResult{Job: job, Err: fmt.Errorf("scan %s interrupted", job.Name)}
+		case <-ticker.C:
+			status, err := zap.GetScanStatus(ctx, sid)
+			if err != nil {
+				continue
+			}
+			setPercent(status)
+			if logStructured {
+				logScanEvent(scanLogEntry{Asset: job.Name, ScanID: sid, Percent: status, Event: "progress"})
+			}
+			if status >= 100 {
+				break pollLoop
+			}
+		}
+	}
+
+	rawAlerts, err := zap.GetAlertsForBase(ctx, job.Target)
+	if err != nil {
+		return scanResult{Job: job, Err: fmt.Errorf("fetching alerts: %w", err)}
+	}
+	return scanResult{Job: job, Grouped: groupAlertsByName(rawAlerts)}
+}
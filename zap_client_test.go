@@ -0,0 +1,227 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func testClient(t *testing.T, handler http.HandlerFunc) *ZapClient {
+	t.Helper()
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	return NewZapClient(srv.URL, "testkey", time.Second)
+}
+
+func TestZapClientStartScan(t *testing.T) {
+	var gotQuery url.Values
+	c := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+		gotQuery = r.URL.Query()
+		w.Write([]byte(`{"scan":"7"}`))
+	})
+
+	sid, err := c.StartScan(context.Background(), "http://example.com", ScanOptions{ContextName: "zapctl-demo", ScanPolicy: "API-Minimal"})
+	if err != nil {
+		t.Fatalf("StartScan: %v", err)
+	}
+	if sid != "7" {
+		t.Fatalf("scan id = %q, want %q", sid, "7")
+	}
+	if got := gotQuery.Get("apikey"); got != "testkey" {
+		t.Errorf("apikey = %q, want %q", got, "testkey")
+	}
+	if got := gotQuery.Get("contextName"); got != "zapctl-demo" {
+		t.Errorf("contextName = %q, want %q", got, "zapctl-demo")
+	}
+	if got := gotQuery.Get("scanPolicyName"); got != "API-Minimal" {
+		t.Errorf("scanPolicyName = %q, want %q", got, "API-Minimal")
+	}
+	if got := gotQuery.Get("inScopeOnly"); got != "true" {
+		t.Errorf("inScopeOnly = %q, want %q when a context is supplied", got, "true")
+	}
+}
+
+func TestZapClientStartScanNoContextIsNotScopeLimited(t *testing.T) {
+	var gotQuery url.Values
+	c := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+		gotQuery = r.URL.Query()
+		w.Write([]byte(`{"scan":"1"}`))
+	})
+
+	if _, err := c.StartScan(context.Background(), "http://example.com", ScanOptions{}); err != nil {
+		t.Fatalf("StartScan: %v", err)
+	}
+	if got := gotQuery.Get("inScopeOnly"); got != "false" {
+		t.Errorf("inScopeOnly = %q, want %q with no context", got, "false")
+	}
+}
+
+func TestZapClientStartScanUnexpectedResponse(t *testing.T) {
+	c := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"nope":"unknown"}`))
+	})
+
+	if _, err := c.StartScan(context.Background(), "http://example.com", ScanOptions{}); err == nil {
+		t.Fatal("expected an error for a response with no recognizable scan id field")
+	}
+}
+
+func TestZapClientGetScanStatus(t *testing.T) {
+	c := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.Query().Get("scanId"); got != "7" {
+			t.Errorf("scanId = %q, want %q", got, "7")
+		}
+		w.Write([]byte(`{"status":"42"}`))
+	})
+
+	status, err := c.GetScanStatus(context.Background(), "7")
+	if err != nil {
+		t.Fatalf("GetScanStatus: %v", err)
+	}
+	if status != 42 {
+		t.Errorf("status = %d, want %d", status, 42)
+	}
+}
+
+func TestZapClientGetAlertsForBase(t *testing.T) {
+	c := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.Query().Get("baseurl"); got != "http://example.com" {
+			t.Errorf("baseurl = %q, want %q", got, "http://example.com")
+		}
+		w.Write([]byte(`{"alerts":[{"alert":"XSS","risk":"High"}]}`))
+	})
+
+	alerts, err := c.GetAlertsForBase(context.Background(), "http://example.com")
+	if err != nil {
+		t.Fatalf("GetAlertsForBase: %v", err)
+	}
+	if len(alerts) != 1 || alerts[0]["alert"] != "XSS" {
+		t.Fatalf("alerts = %v, want one XSS alert", alerts)
+	}
+}
+
+func TestZapClientConfigureContextNoScope(t *testing.T) {
+	c := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Fatalf("unexpected request to %s; ConfigureContext should not call ZAP for an asset with no scope and no auth profile", r.URL.Path)
+	})
+
+	name, err := c.ConfigureContext(context.Background(), Asset{Name: "demo"})
+	if err != nil {
+		t.Fatalf("ConfigureContext: %v", err)
+	}
+	if name != "" {
+		t.Errorf("contextName = %q, want empty for an asset with no scope", name)
+	}
+}
+
+func TestZapClientConfigureContextAuthOnly(t *testing.T) {
+	var sawNewContext bool
+	var ruleDescription, ruleURL string
+	c := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/JSON/context/action/newContext/":
+			sawNewContext = true
+		case "/JSON/replacer/action/addRule/":
+			ruleDescription = r.URL.Query().Get("description")
+			ruleURL = r.URL.Query().Get("url")
+		}
+		w.Write([]byte(`{}`))
+	})
+
+	asset := Asset{
+		Name:        "demo",
+		URL:         "http://example.com",
+		AuthProfile: &AuthProfile{Type: "bearer", Token: "abc"},
+	}
+	name, err := c.ConfigureContext(context.Background(), asset)
+	if err != nil {
+		t.Fatalf("ConfigureContext: %v", err)
+	}
+	if name != "" {
+		t.Errorf("contextName = %q, want empty since no ZAP context was created", name)
+	}
+	if sawNewContext {
+		t.Error("ConfigureContext called newContext for an asset with no Scope")
+	}
+	if ruleDescription != "zapctl-auth-demo" {
+		t.Errorf("replacer rule description = %q, want it scoped to the asset", ruleDescription)
+	}
+	if ruleURL == "" {
+		t.Error("replacer rule has no url scope; it would apply to every asset's requests")
+	}
+}
+
+func TestZapClientConfigureContextTwoAssetsDontShareAuthRule(t *testing.T) {
+	var descriptions []string
+	c := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/JSON/replacer/action/addRule/" {
+			descriptions = append(descriptions, r.URL.Query().Get("description"))
+		}
+		w.Write([]byte(`{}`))
+	})
+
+	for _, name := range []string{"a", "b"} {
+		if _, err := c.ConfigureContext(context.Background(), Asset{
+			Name:        name,
+			URL:         "http://" + name + ".example.com",
+			AuthProfile: &AuthProfile{Type: "bearer", Token: "tok-" + name},
+		}); err != nil {
+			t.Fatalf("ConfigureContext(%s): %v", name, err)
+		}
+	}
+	if len(descriptions) != 2 || descriptions[0] == descriptions[1] {
+		t.Errorf("expected two distinct replacer rule descriptions, got %v", descriptions)
+	}
+}
+
+func TestZapClientTeardownAuthHeader(t *testing.T) {
+	var removedDescription string
+	c := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/JSON/replacer/action/removeRule/" {
+			removedDescription = r.URL.Query().Get("description")
+		}
+		w.Write([]byte(`{}`))
+	})
+
+	asset := Asset{Name: "demo", AuthProfile: &AuthProfile{Type: "bearer", Token: "abc"}}
+	if err := c.TeardownAuthHeader(context.Background(), asset); err != nil {
+		t.Fatalf("TeardownAuthHeader: %v", err)
+	}
+	if removedDescription != "zapctl-auth-demo" {
+		t.Errorf("removed rule description = %q, want %q", removedDescription, "zapctl-auth-demo")
+	}
+}
+
+func TestZapClientTeardownAuthHeaderNoAuthProfileIsNoop(t *testing.T) {
+	c := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Fatalf("unexpected request to %s; TeardownAuthHeader should be a no-op for an asset with no AuthProfile", r.URL.Path)
+	})
+
+	if err := c.TeardownAuthHeader(context.Background(), Asset{Name: "demo"}); err != nil {
+		t.Fatalf("TeardownAuthHeader: %v", err)
+	}
+}
+
+func TestZapClientConfigureContextWithScope(t *testing.T) {
+	var includedRegex string
+	c := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/JSON/context/action/includeInContext/" {
+			includedRegex = r.URL.Query().Get("regex")
+		}
+		w.Write([]byte(`{}`))
+	})
+
+	name, err := c.ConfigureContext(context.Background(), Asset{Name: "demo", Scope: []string{"^http://example.com/.*$"}})
+	if err != nil {
+		t.Fatalf("ConfigureContext: %v", err)
+	}
+	if name != "zapctl-demo" {
+		t.Errorf("contextName = %q, want %q", name, "zapctl-demo")
+	}
+	if includedRegex != "^http://example.com/.*$" {
+		t.Errorf("included regex = %q, want the asset's scope regex", includedRegex)
+	}
+}